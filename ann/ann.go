@@ -0,0 +1,316 @@
+// Package ann provides approximate nearest-neighbor indexes for the
+// vector mappings produced by package litevec.
+package ann
+
+import (
+	"container/heap"
+	"encoding/gob"
+	"io"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ANNIndex is an approximate nearest-neighbor index over float64 vectors
+// identified by caller-assigned integer IDs.
+type ANNIndex interface {
+	Insert(id int, vec []float64)
+	Search(query []float64, k, ef int) []int
+}
+
+// Node is a single HNSW graph node: the level it was promoted to and its
+// neighbor list at each layer up to that level.
+type Node struct {
+	ID        int
+	Level     int
+	Neighbors [][]int
+}
+
+// HNSW is a Hierarchical Navigable Small World graph, following Malkov &
+// Yashunin. Layers above 0 thin out the graph for fast coarse descent;
+// layer 0 holds every node and is where the final beam search runs.
+type HNSW struct {
+	M              int
+	Mmax0          int
+	ML             float64
+	EfConstruction int
+	EntryPoint     int
+	MaxLevel       int
+	Nodes          map[int]*Node
+	Vectors        map[int][]float64
+
+	rnd *rand.Rand
+}
+
+// NewHNSW creates an empty index. M bounds the number of neighbors kept
+// per node per layer (2M at layer 0); efConstruction controls the size
+// of the candidate list explored while inserting.
+func NewHNSW(M, efConstruction int) *HNSW {
+	return &HNSW{
+		M:              M,
+		Mmax0:          2 * M,
+		ML:             1 / math.Log(float64(M)),
+		EfConstruction: efConstruction,
+		EntryPoint:     -1,
+		MaxLevel:       -1,
+		Nodes:          make(map[int]*Node),
+		Vectors:        make(map[int][]float64),
+		rnd:            rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (h *HNSW) randomLevel() int {
+	u := h.rnd.Float64()
+	if u <= 0 {
+		u = math.SmallestNonzeroFloat64
+	}
+	return int(math.Floor(-math.Log(u) * h.ML))
+}
+
+func (h *HNSW) distance(a, b []float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return -dot
+}
+
+// Insert adds vec under id, assigning it a random level l =
+// floor(-ln(U)*mL) and wiring it into every layer from l down to 0.
+func (h *HNSW) Insert(id int, vec []float64) {
+	h.Vectors[id] = vec
+	level := h.randomLevel()
+	nd := &Node{ID: id, Level: level, Neighbors: make([][]int, level+1)}
+	h.Nodes[id] = nd
+
+	if h.EntryPoint == -1 {
+		h.EntryPoint = id
+		h.MaxLevel = level
+		return
+	}
+
+	ep := h.EntryPoint
+	for l := h.MaxLevel; l > level; l-- {
+		ep = h.greedyClosest(vec, ep, l)
+	}
+
+	top := level
+	if h.MaxLevel < top {
+		top = h.MaxLevel
+	}
+	for l := top; l >= 0; l-- {
+		candidates := h.searchLayer(vec, ep, h.EfConstruction, l)
+		M := h.M
+		if l == 0 {
+			M = h.Mmax0
+		}
+		neighbors := h.selectNeighborsHeuristic(vec, candidates, M)
+		nd.Neighbors[l] = neighbors
+		for _, nb := range neighbors {
+			h.addNeighbor(nb, id, l)
+		}
+		if len(candidates) > 0 {
+			ep = candidates[0].id
+		}
+	}
+
+	if level > h.MaxLevel {
+		h.MaxLevel = level
+		h.EntryPoint = id
+	}
+}
+
+func (h *HNSW) addNeighbor(id, newNeighbor, layer int) {
+	nd := h.Nodes[id]
+	if layer > nd.Level {
+		return
+	}
+	nd.Neighbors[layer] = append(nd.Neighbors[layer], newNeighbor)
+	M := h.M
+	if layer == 0 {
+		M = h.Mmax0
+	}
+	if len(nd.Neighbors[layer]) <= M {
+		return
+	}
+	candidates := make([]candidate, len(nd.Neighbors[layer]))
+	for i, n := range nd.Neighbors[layer] {
+		candidates[i] = candidate{id: n, dist: h.distance(h.Vectors[id], h.Vectors[n])}
+	}
+	nd.Neighbors[layer] = h.selectNeighborsHeuristic(h.Vectors[id], candidates, M)
+}
+
+// greedyClosest descends from ep at layer, repeatedly stepping to the
+// single closest neighbor until no neighbor improves on the current best.
+func (h *HNSW) greedyClosest(vec []float64, ep, layer int) int {
+	best := ep
+	bestDist := h.distance(vec, h.Vectors[best])
+	for improved := true; improved; {
+		improved = false
+		for _, nb := range h.Nodes[best].Neighbors[layer] {
+			if d := h.distance(vec, h.Vectors[nb]); d < bestDist {
+				bestDist = d
+				best = nb
+				improved = true
+			}
+		}
+	}
+	return best
+}
+
+type candidate struct {
+	id   int
+	dist float64
+}
+
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].dist < h[j].dist }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool  { return h[i].dist > h[j].dist }
+func (h maxHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x interface{}) { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// searchLayer runs the standard HNSW beam search at layer: a min-heap of
+// unexplored candidates and a max-heap of the best ef results found so
+// far, expanding the closest unvisited candidate until none can improve
+// on the current worst result. Returns up to ef candidates sorted by
+// ascending distance.
+func (h *HNSW) searchLayer(vec []float64, ep, ef, layer int) []candidate {
+	visited := map[int]bool{ep: true}
+	start := candidate{id: ep, dist: h.distance(vec, h.Vectors[ep])}
+
+	candidates := &minHeap{start}
+	results := &maxHeap{start}
+	heap.Init(candidates)
+	heap.Init(results)
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(candidate)
+		worst := (*results)[0]
+		if c.dist > worst.dist && results.Len() >= ef {
+			break
+		}
+		for _, nb := range h.Nodes[c.id].Neighbors[layer] {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			d := h.distance(vec, h.Vectors[nb])
+			worst = (*results)[0]
+			if results.Len() < ef || d < worst.dist {
+				heap.Push(candidates, candidate{id: nb, dist: d})
+				heap.Push(results, candidate{id: nb, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	rtn := make([]candidate, results.Len())
+	copy(rtn, *results)
+	sortByDist(rtn)
+	return rtn
+}
+
+func sortByDist(c []candidate) {
+	for i := 1; i < len(c); i++ {
+		for j := i; j > 0 && c[j].dist < c[j-1].dist; j-- {
+			c[j], c[j-1] = c[j-1], c[j]
+		}
+	}
+}
+
+// selectNeighborsHeuristic prunes candidates down to at most M, keeping
+// a candidate e only if no neighbor already selected is closer to e than
+// the query q is - this favors spreading neighbors across directions
+// over clustering them all on the closest side of q.
+func (h *HNSW) selectNeighborsHeuristic(q []float64, candidates []candidate, M int) []int {
+	sorted := make([]candidate, len(candidates))
+	copy(sorted, candidates)
+	sortByDist(sorted)
+
+	var selected []candidate
+	for _, e := range sorted {
+		if len(selected) >= M {
+			break
+		}
+		qd := h.distance(q, h.Vectors[e.id])
+		keep := true
+		for _, s := range selected {
+			if h.distance(h.Vectors[s.id], h.Vectors[e.id]) < qd {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, e)
+		}
+	}
+
+	rtn := make([]int, len(selected))
+	for i, s := range selected {
+		rtn[i] = s.id
+	}
+	return rtn
+}
+
+// Search finds the k approximate nearest neighbors of query, exploring a
+// candidate list of size ef (ef should be >= k; larger ef trades speed
+// for recall). Returns IDs sorted by ascending distance (most similar
+// first).
+func (h *HNSW) Search(query []float64, k, ef int) []int {
+	if h.EntryPoint == -1 {
+		return nil
+	}
+	ep := h.EntryPoint
+	for l := h.MaxLevel; l > 0; l-- {
+		ep = h.greedyClosest(query, ep, l)
+	}
+	candidates := h.searchLayer(query, ep, ef, 0)
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+	rtn := make([]int, k)
+	for i := 0; i < k; i++ {
+		rtn[i] = candidates[i].id
+	}
+	return rtn
+}
+
+// Save persists the graph and its vectors so a later Load can reopen the
+// index without rebuilding it.
+func (h *HNSW) Save(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(h)
+}
+
+// Load reads back an index previously written with Save.
+func Load(r io.Reader) (*HNSW, error) {
+	h := &HNSW{}
+	if err := gob.NewDecoder(r).Decode(h); err != nil {
+		return nil, err
+	}
+	h.rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	return h, nil
+}
@@ -0,0 +1,152 @@
+package litevec
+
+import (
+	"io"
+	"math"
+
+	"github.com/james-bowman/sparse"
+)
+
+/// Corpus accumulates tokens incrementally, growing its vocabulary and
+/// co-occurrence counts as text arrives instead of requiring the whole
+/// stream to be buffered in memory up front. Feed it via AddReader or
+/// AddTokens as many times as needed, then pull a Doc or PMIs snapshot
+/// that reflects everything seen so far.
+type Corpus struct {
+	Tokens        Text
+	TokenIndices  map[string]int
+	maxJuxt       int
+	unigramCounts []float64
+	coOccur       *sparse.DOK
+	// processedHi is the highest token index updateCoOccur has already
+	// folded into coOccur, or -1 before any tokens have been processed.
+	// Resuming from here (rather than recomputing a window back from
+	// each call's start) is what lets chunked AddTokens calls reproduce
+	// a single call over the same tokens with no gap or overlap at the
+	// chunk boundary.
+	processedHi int
+}
+
+/// NewCorpus creates an empty streaming corpus. maxJuxt is the skipgram
+/// window radius used when folding newly added tokens into the
+/// co-occurrence matrix, matching the maxJuxt argument to SkipgramPs/PMIs.
+func NewCorpus(maxJuxt int) *Corpus {
+	return &Corpus{
+		TokenIndices: make(map[string]int),
+		maxJuxt:      maxJuxt,
+		processedHi:  -1,
+	}
+}
+
+func (C *Corpus) VocabLength() int {
+	return len(C.TokenIndices)
+}
+
+/// AddReader reads and tokenizes raw text the same way ReadText does,
+/// then folds the result into the corpus.
+func (C *Corpus) AddReader(r io.Reader) error {
+	text, err := ReadText(r)
+	if err != nil {
+		return err
+	}
+	C.AddTokens(text)
+	return nil
+}
+
+/// AddTokens extends the corpus with already-tokenized text, growing the
+/// vocabulary and updating unigram counts and co-occurrence entries for
+/// the newly added region without touching prior data.
+func (C *Corpus) AddTokens(text Text) {
+	if len(text) == 0 {
+		return
+	}
+	for _, t := range text {
+		if _, indexed := C.TokenIndices[t]; !indexed {
+			C.TokenIndices[t] = len(C.TokenIndices)
+			C.unigramCounts = append(C.unigramCounts, 0)
+		}
+	}
+	C.Tokens = append(C.Tokens, text...)
+	for _, t := range text {
+		C.unigramCounts[C.TokenIndices[t]]++
+	}
+	C.growCoOccur()
+	C.updateCoOccur()
+}
+
+func (C *Corpus) growCoOccur() {
+	n := len(C.TokenIndices)
+	if C.coOccur == nil {
+		C.coOccur = sparse.NewDOK(n, n)
+		return
+	}
+	r, _ := C.coOccur.Dims()
+	if r >= n {
+		return
+	}
+	grown := sparse.NewDOK(n, n)
+	C.coOccur.DoNonZero(func(i, j int, v float64) {
+		grown.Set(i, j, v)
+	})
+	C.coOccur = grown
+}
+
+/// updateCoOccur folds every token index not yet covered by processedHi
+/// into the co-occurrence matrix, following the same skipgram weighting
+/// as SkipgramPs. Resuming from processedHi (instead of rederiving a lower
+/// bound from this call's newly added tokens) is what keeps chunked
+/// AddTokens calls gap- and overlap-free at their boundary: a token whose
+/// window couldn't be fully processed yet because it ran past the tokens
+/// seen so far is picked up by the next call instead of being skipped.
+func (C *Corpus) updateCoOccur() {
+	lo := C.processedHi + 1
+	if lo < C.maxJuxt {
+		lo = C.maxJuxt
+	}
+	// hi/j bounds mirror SkipgramPs's "i < len(Tokens)-maxJuxt-1" exactly,
+	// so the streaming and batch co-occurrence computations agree.
+	hi := len(C.Tokens) - C.maxJuxt - 2
+	for i := lo; i <= hi; i++ {
+		for j := 1; j <= C.maxJuxt; j++ {
+			a := C.Tokens[i]
+			for _, b := range []string{C.Tokens[i+j], C.Tokens[i-j]} {
+				a_i := C.TokenIndices[a]
+				b_i := C.TokenIndices[b]
+				displacement := math.Abs(float64(j))
+				C.coOccur.Set(a_i, b_i, C.coOccur.At(a_i, b_i)+1/displacement)
+			}
+		}
+	}
+	if hi >= lo {
+		C.processedHi = hi
+	}
+}
+
+/// UnigramPs returns the current unigram probability distribution
+/// indexed by token ID, matching Doc.UnigramPs.
+func (C *Corpus) UnigramPs() (rtn []float64) {
+	rtn = make([]float64, len(C.unigramCounts))
+	for i, v := range C.unigramCounts {
+		rtn[i] = v / float64(len(C.Tokens))
+	}
+	return
+}
+
+/// Doc snapshots the corpus seen so far as an ordinary Doc, usable with
+/// the rest of the package's API.
+func (C *Corpus) Doc() Doc {
+	return Doc{Tokens: C.Tokens, TokenIndices: C.TokenIndices}
+}
+
+/// PMIs snapshots the current co-occurrence matrix as a normalized
+/// pointwise mutual information matrix, without reprocessing any of the
+/// tokens added so far.
+func (C *Corpus) PMIs() (N *sparse.CSR) {
+	U := C.UnigramPs()
+	n := len(C.Tokens)
+	N = C.coOccur.ToCSR()
+	N.DoNonZero(func(i, j int, v float64) {
+		N.Set(i, j, math.Log(v/float64(n)/(U[i]*U[j])))
+	})
+	return
+}
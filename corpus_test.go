@@ -0,0 +1,40 @@
+package litevec
+
+import "testing"
+
+// TestCorpusChunkedMatchesSingle guards against updateCoOccur's boundary
+// handling silently diverging from a single-shot AddTokens call: feeding
+// the same tokens in two chunks must fold identically into coOccur (and
+// so into PMIs) as feeding them all at once, which is the entire point of
+// Corpus being "streaming".
+func TestCorpusChunkedMatchesSingle(t *testing.T) {
+	text := MkText(sampleText)
+
+	single := NewCorpus(2)
+	single.AddTokens(text)
+
+	chunked := NewCorpus(2)
+	mid := len(text) / 2
+	chunked.AddTokens(text[:mid])
+	chunked.AddTokens(text[mid:])
+
+	singleN := single.PMIs()
+	chunkedN := chunked.PMIs()
+
+	rows, cols := singleN.Dims()
+	cRows, cCols := chunkedN.Dims()
+	if rows != cRows || cols != cCols {
+		t.Fatalf("dimension mismatch: single %dx%d, chunked %dx%d", rows, cols, cRows, cCols)
+	}
+
+	singleN.DoNonZero(func(i, j int, v float64) {
+		if cv := chunkedN.At(i, j); cv != v {
+			t.Errorf("PMIs(%d,%d) = %v single vs %v chunked", i, j, v, cv)
+		}
+	})
+	chunkedN.DoNonZero(func(i, j int, v float64) {
+		if sv := singleN.At(i, j); sv != v {
+			t.Errorf("PMIs(%d,%d) = %v chunked vs %v single", i, j, v, sv)
+		}
+	})
+}
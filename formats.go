@@ -0,0 +1,154 @@
+package litevec
+
+import (
+	"bufio"
+	bin "encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+/// WriteWord2Vec writes M in the standard word2vec format: a
+/// "<vocab_size> <dim>\n" header followed by one entry per vocabulary
+/// term. If binary is true, each entry is "<word> " followed by dim
+/// little-endian float32s and a trailing newline; otherwise it's
+/// "<word> <f1> <f2> ... <fn>\n" with space-separated floats.
+func (M Model) WriteWord2Vec(w io.Writer, binary bool) error {
+	vocab := M.VecMapping.Vocab()
+	dim := 0
+	if len(vocab) > 0 {
+		dim = M.VecMapping[vocab[0]].Len()
+	}
+	if _, err := fmt.Fprintf(w, "%d %d\n", len(vocab), dim); err != nil {
+		return err
+	}
+	for _, t := range vocab {
+		vec := M.VecMapping[t]
+		if binary {
+			if _, err := io.WriteString(w, t+" "); err != nil {
+				return err
+			}
+			buf := make([]byte, 4*dim)
+			for i := 0; i < dim; i++ {
+				bin.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(vec.AtVec(i))))
+			}
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		} else {
+			parts := make([]string, dim)
+			for i := 0; i < dim; i++ {
+				parts[i] = strconv.FormatFloat(vec.AtVec(i), 'f', 6, 64)
+			}
+			if _, err := fmt.Fprintf(w, "%s %s\n", t, strings.Join(parts, " ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/// ReadWord2Vec reads a Model back from the standard word2vec format
+/// written by WriteWord2Vec (binary must match how it was written).
+func ReadWord2Vec(r io.Reader, binary bool) (rtn Model, err error) {
+	br := bufio.NewReader(r)
+	header, err := br.ReadString('\n')
+	if err != nil {
+		return
+	}
+	var vocabSize, dim int
+	if _, err = fmt.Sscanf(header, "%d %d", &vocabSize, &dim); err != nil {
+		return
+	}
+
+	vocab := make(Text, vocabSize)
+	data := make([]float64, 0, vocabSize*dim)
+	for i := 0; i < vocabSize; i++ {
+		var word string
+		vec := make([]float64, dim)
+		if binary {
+			if word, err = br.ReadString(' '); err != nil {
+				return
+			}
+			word = strings.TrimSuffix(word, " ")
+			buf := make([]byte, 4*dim)
+			if _, err = io.ReadFull(br, buf); err != nil {
+				return
+			}
+			for j := 0; j < dim; j++ {
+				vec[j] = float64(math.Float32frombits(bin.LittleEndian.Uint32(buf[j*4:])))
+			}
+			if _, err = br.ReadByte(); err != nil && err != io.EOF {
+				return
+			}
+			err = nil
+		} else {
+			var line string
+			if line, err = br.ReadString('\n'); err != nil && err != io.EOF {
+				return
+			}
+			err = nil
+			fields := strings.Fields(line)
+			word = fields[0]
+			for j := 0; j < dim; j++ {
+				if vec[j], err = strconv.ParseFloat(fields[j+1], 64); err != nil {
+					return
+				}
+			}
+		}
+		vocab[i] = word
+		data = append(data, vec...)
+	}
+
+	rtn.Matrix = mat.NewDense(vocabSize, dim, data)
+	rtn.VecMapping = make(VecMapping, vocabSize)
+	for i, w := range vocab {
+		rtn.VecMapping[w] = rtn.Matrix.RowView(i)
+	}
+	return
+}
+
+/// ReadGloVe reads a Model from the GloVe text format: one
+/// "<word> <f1> <f2> ... <fn>\n" line per vocabulary term, with no
+/// header - dimensionality is inferred from the first line.
+func ReadGloVe(r io.Reader) (rtn Model, err error) {
+	scanner := bufio.NewScanner(r)
+	var vocab Text
+	var data []float64
+	dim := -1
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if dim == -1 {
+			dim = len(fields) - 1
+		}
+		vec := make([]float64, dim)
+		for j := 0; j < dim; j++ {
+			if vec[j], err = strconv.ParseFloat(fields[j+1], 64); err != nil {
+				return
+			}
+		}
+		vocab = append(vocab, fields[0])
+		data = append(data, vec...)
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	rtn.Matrix = mat.NewDense(len(vocab), dim, data)
+	rtn.VecMapping = make(VecMapping, len(vocab))
+	for i, w := range vocab {
+		rtn.VecMapping[w] = rtn.Matrix.RowView(i)
+	}
+	return
+}
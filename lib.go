@@ -1,19 +1,16 @@
 package litevec
 
 import (
-	"bytes"
+	"bufio"
+	"encoding/gob"
+	"fmt"
 	"io"
 	"math"
 	"sort"
 	"strings"
-	"unicode"
-
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
 
+	"github.com/iseurie/litevec/ann"
 	"github.com/james-bowman/sparse"
-	"github.com/jdkato/prose/tokenize"
 	"gonum.org/v1/gonum/mat"
 )
 
@@ -23,26 +20,22 @@ type VecMapping map[string]mat.Vector
 type Model struct {
 	VecMapping
 	Matrix *mat.Dense
+	Index  *ann.HNSW
+	vocab  Text
 }
 
-func ReadText(raw io.Reader) (rtn Text, err error) {
-	pipeline := []transform.Transformer{
-		norm.NFD,
-		runes.Remove(runes.In(unicode.Mn)),
-		runes.Map(unicode.ToLower),
+/// ReadText tokenizes raw text with the given Analyzer, or with
+/// GenericUnicodeAnalyzer's NFD -> strip marks -> lowercase pipeline if
+/// none is supplied.
+func ReadText(raw io.Reader, analyzer ...Analyzer) (rtn Text, err error) {
+	if len(analyzer) > 0 {
+		return analyzer[0].Analyze(raw)
 	}
-	tx := transform.Chain(pipeline...)
-	rd := transform.NewReader(raw, tx)
-	var buf bytes.Buffer
-	_, err = buf.ReadFrom(rd)
-	if err != nil {
-		return
-	}
-	return tokenize.TextToWords(buf.String()), nil
+	return GenericUnicodeAnalyzer().Analyze(raw)
 }
 
-func MkText(s string) (rtn Text) {
-	rtn, _ = ReadText(strings.NewReader(s))
+func MkText(s string, analyzer ...Analyzer) (rtn Text) {
+	rtn, _ = ReadText(strings.NewReader(s), analyzer...)
 	return
 }
 
@@ -63,8 +56,19 @@ func (D Doc) VocabLength() int {
 	return len(D.TokenIndices)
 }
 
+/// ReadDoc tokenizes raw text with the given Analyzer (or the default,
+/// see ReadText) and builds a Doc from the result.
+func ReadDoc(raw io.Reader, analyzer ...Analyzer) (rtn Doc, err error) {
+	text, err := ReadText(raw, analyzer...)
+	if err != nil {
+		return
+	}
+	return MkDoc(text), nil
+}
+
 func MkDoc(text Text) (rtn Doc) {
 	rtn.Tokens = text
+	rtn.TokenIndices = make(map[string]int, len(text))
 	i := 0
 	for _, t := range text {
 		if _, indexed := rtn.TokenIndices[t]; !indexed {
@@ -82,7 +86,9 @@ func (D Doc) UnigramPs() (rtn []float64) {
 		rtn[D.TokenIndices[t]]++
 	}
 	for i := 0; i < len(rtn); i++ {
-		rtn[i] /= float64(len(rtn))
+		// Normalize by token count, not vocab size: this is P(token),
+		// the probability of drawing that word from the token stream.
+		rtn[i] /= float64(len(D.Tokens))
 	}
 	return
 }
@@ -91,9 +97,14 @@ func (D Doc) UnigramPs() (rtn []float64) {
 func (D Doc) SkipgramPs(maxJuxt int) *sparse.CSR {
 	n := D.VocabLength()
 	s := n / 10
-	rtn := sparse.NewCSR(n, n, make([]int, s), make([]int, s), make([]float64, s))
+	// Indptr holds one entry per row plus a sentinel, so it must be sized
+	// n+1 regardless of how many nonzeros we expect; ja/data only need s
+	// as a capacity hint since Set grows them as nonzeros are inserted.
+	rtn := sparse.NewCSR(n, n, make([]int, n+1), make([]int, 0, s), make([]float64, 0, s))
 	for i := maxJuxt; i < len(D.Tokens)-maxJuxt-1; i++ {
-		for j := 0; j < maxJuxt; j++ {
+		// j starts at 1: j=0 would pair a token with itself at distance
+		// 0, blowing up the 1/displacement weight to +Inf.
+		for j := 1; j <= maxJuxt; j++ {
 			a := D.Tokens[i]
 			for _, b := range []string{D.Tokens[i+j], D.Tokens[i-j]} {
 				a_i := D.TokenIndices[a]
@@ -121,19 +132,120 @@ func (D Doc) PMIs(maxJuxt int) (N *sparse.CSR) {
 	return
 }
 
-func (D Doc) WordVecs(maxJuxt int, maxDim *int) (rtn Model) {
-	svd := new(mat.SVD)
-	sparse := D.PMIs(maxJuxt)
-	svd.Factorize(sparse, mat.SVDFull)
-	mat := svd.UTo(nil)
-	rtn.Matrix = mat
+/// PPMIs returns a shifted positive-PMI matrix, following Levy &
+/// Goldberg's SGNS-equivalent weighting: max(0, PMI(i,j) - log(negSamples)).
+/// negSamples plays the same role as SGNS's negative-sample count - higher
+/// values push more low-PMI pairs to zero. This is what WordVecs factorizes,
+/// since it keeps the sparse matrix closer to SGNS-quality than raw PMIs.
+func (D Doc) PPMIs(maxJuxt int, negSamples int) (N *sparse.CSR) {
+	N = D.PMIs(maxJuxt)
+	shift := math.Log(float64(negSamples))
+	N.DoNonZero(func(i, j int, v float64) {
+		if ppmi := v - shift; ppmi > 0 {
+			N.Set(i, j, ppmi)
+		} else {
+			N.Set(i, j, 0)
+		}
+	})
+	return
+}
+
+/// WordVecs factorizes D's shifted-PPMI matrix with randomized truncated
+/// SVD (Halko/Martinsson) instead of a full dense decomposition, so it
+/// stays tractable on vocabularies far larger than a full O(V^3) SVD can
+/// handle. maxDim caps the embedding dimension (nil keeps the full
+/// vocabulary-sized basis); RSVDOptions tune the approximation.
+func (D Doc) WordVecs(maxJuxt int, maxDim *int, opts ...RSVDOption) (rtn Model) {
+	cfg := defaultRSVDConfig()
+	for _, o := range opts {
+		o(&cfg)
+	}
+	A := D.PPMIs(maxJuxt, cfg.negSamples)
+	n, _ := A.Dims()
+	k := n
+	if maxDim != nil && *maxDim < k {
+		k = *maxDim
+	}
+
+	rtn.Matrix = randomizedSVD(A, k, cfg)
 	V := D.Vocab()
+	rtn.VecMapping = make(VecMapping, len(V))
 	for i := 0; i < len(V); i++ {
 		rtn.VecMapping[V[i]] = rtn.Matrix.RowView(i)
 	}
 	return
 }
 
+/// BuildHNSW indexes the model's vectors with an HNSW graph so later
+/// Nearest calls can run in sub-linear time instead of scanning the
+/// whole vocabulary. m bounds neighbors kept per node per layer;
+/// efConstruction controls how wide a candidate list is explored while
+/// inserting each vector.
+func (M *Model) BuildHNSW(m, efConstruction int) {
+	M.vocab = M.VecMapping.Vocab()
+	M.Index = ann.NewHNSW(m, efConstruction)
+	for i, t := range M.vocab {
+		M.Index.Insert(i, vecToSlice(M.VecMapping[t]))
+	}
+}
+
+/// Nearest returns the k approximate nearest neighbors of t using the
+/// HNSW index built by BuildHNSW, exploring a candidate list of size ef
+/// (ef should be >= k; larger ef trades speed for recall). t itself is
+/// excluded from the results.
+func (M *Model) Nearest(t string, k, ef int) Text {
+	// Search for k+1 and drop t below, since t's own vector is in the
+	// index and would otherwise always surface as its nearest match.
+	ids := M.Index.Search(vecToSlice(M.VecMapping[t]), k+1, ef)
+	rtn := make(Text, 0, k)
+	for _, id := range ids {
+		if M.vocab[id] == t {
+			continue
+		}
+		rtn = append(rtn, M.vocab[id])
+		if len(rtn) == k {
+			break
+		}
+	}
+	return rtn
+}
+
+/// SaveHNSW persists the HNSW index built by BuildHNSW, along with the
+/// vocabulary ordering it was built against, so LoadHNSW can reopen it
+/// without rebuilding.
+func (M *Model) SaveHNSW(w io.Writer) error {
+	if err := gob.NewEncoder(w).Encode(M.vocab); err != nil {
+		return err
+	}
+	return M.Index.Save(w)
+}
+
+/// LoadHNSW reads back an index previously written with SaveHNSW.
+func (M *Model) LoadHNSW(r io.Reader) error {
+	// gob buffers (and so reads past the vocabulary) on a reader that is
+	// not an io.ByteReader; share one buffered reader with ann.Load.
+	if _, ok := r.(io.ByteReader); !ok {
+		r = bufio.NewReader(r)
+	}
+	if err := gob.NewDecoder(r).Decode(&M.vocab); err != nil {
+		return err
+	}
+	idx, err := ann.Load(r)
+	if err != nil {
+		return err
+	}
+	M.Index = idx
+	return nil
+}
+
+func vecToSlice(v mat.Vector) []float64 {
+	rtn := make([]float64, v.Len())
+	for i := range rtn {
+		rtn[i] = v.AtVec(i)
+	}
+	return rtn
+}
+
 func (m VecMapping) CosSim(a, b string) float64 {
 	return mat.Dot(m[a], m[b])
 }
@@ -163,7 +275,8 @@ func (m VecMapping) StrEmbedding(src string) *mat.VecDense {
 /// how important it is to the document overall.
 type Incidency map[string]float64
 
-func (I Incidency) Of(D Doc, maxJuxt int) {
+/// NewIncidency computes the Incidency of every term in D.
+func NewIncidency(D Doc, maxJuxt int) (I Incidency) {
 	I = make(Incidency, D.VocabLength())
 	S := D.PMIs(maxJuxt)
 	for t, i := range D.TokenIndices {
@@ -178,6 +291,7 @@ func (I Incidency) Of(D Doc, maxJuxt int) {
 		I[t] /= float64(len(I))
 		I[t] = 1 / v
 	}
+	return
 }
 
 func (I Incidency) Keywords(n *int) (rtn Text) {
@@ -195,12 +309,49 @@ func (I Incidency) Keywords(n *int) (rtn Text) {
 	return
 }
 
+/// KeywordsMMR reranks Keywords with Maximal Marginal Relevance, using D's
+/// own word vectors (via WordVecs) to penalize candidates that are near-
+/// duplicates of ones already chosen. As with Keywords, relevance follows
+/// I's ascending ordering, so lambda=1 reproduces Keywords exactly.
+func (I Incidency) KeywordsMMR(D Doc, k int, lambda float64) Text {
+	m := D.WordVecs(mmrMaxJuxt, nil).VecMapping
+	pool := make(map[string]bool, len(I))
+	for t := range I {
+		pool[t] = true
+	}
+	if k > len(pool) {
+		k = len(pool)
+	}
+	relevance := func(t string) float64 { return -I[t] }
+	rtn := make(Text, 0, k)
+	for len(rtn) < k {
+		best, bestScore := "", math.Inf(-1)
+		for t := range pool {
+			score := lambda*relevance(t) - (1-lambda)*maxCosSim(m, t, rtn)
+			// Break ties lexicographically so results don't depend on
+			// Go's randomized map iteration order.
+			if score > bestScore || (score == bestScore && t < best) {
+				best, bestScore = t, score
+			}
+		}
+		rtn = append(rtn, best)
+		delete(pool, best)
+	}
+	return rtn
+}
+
+/// mmrMaxJuxt is the skipgram window KeywordsMMR always uses to derive
+/// term vectors for its diversity term; KeywordsMMR takes no window or
+/// vectors from the caller.
+const mmrMaxJuxt = 2
+
 func (D Doc) KeyVecs(maxJuxt int, maxDim *int) (rtn VecMapping) {
-	var I Incidency
-	I.Of(D, maxJuxt)
+	I := NewIncidency(D, maxJuxt)
 	K := I.Keywords(maxDim)
+	M := D.WordVecs(maxJuxt, maxDim)
+	rtn = make(VecMapping, len(K))
 	for _, k := range K {
-		rtn[k] = D.WordVecs(maxJuxt, maxDim).VecMapping[k]
+		rtn[k] = M.VecMapping[k]
 	}
 	return
 }
@@ -216,9 +367,62 @@ func (m VecMapping) Constellation(t string, n *int) Text {
 	return V[:*n]
 }
 
+/// ConstellationMMR reranks Constellation with Maximal Marginal Relevance:
+/// at each step it picks the candidate x maximizing
+/// lambda*relevance(x) - (1-lambda)*max_{y in selected} CosSim(x, y),
+/// so that near-duplicate terms no longer crowd out diverse ones. relevance
+/// follows Constellation's own (ascending-CosSim) notion of closeness, so
+/// lambda=1 reproduces Constellation's ordering exactly; lambda<1 trades
+/// relevance for diversity.
+func (m VecMapping) ConstellationMMR(t string, k int, lambda float64) Text {
+	pool := make(map[string]bool, len(m))
+	for v := range m {
+		if v != t {
+			pool[v] = true
+		}
+	}
+	if k > len(pool) {
+		k = len(pool)
+	}
+	relevance := func(x string) float64 { return -m.CosSim(t, x) }
+	rtn := make(Text, 0, k)
+	for len(rtn) < k {
+		best, bestScore := "", math.Inf(-1)
+		for x := range pool {
+			score := lambda*relevance(x) - (1-lambda)*maxCosSim(m, x, rtn)
+			// Break ties lexicographically so results don't depend on
+			// Go's randomized map iteration order.
+			if score > bestScore || (score == bestScore && x < best) {
+				best, bestScore = x, score
+			}
+		}
+		rtn = append(rtn, best)
+		delete(pool, best)
+	}
+	return rtn
+}
+
+func maxCosSim(m VecMapping, x string, selected Text) float64 {
+	if len(selected) == 0 {
+		return 0
+	}
+	rtn := math.Inf(-1)
+	for _, s := range selected {
+		if sim := m.CosSim(x, s); sim > rtn {
+			rtn = sim
+		}
+	}
+	return rtn
+}
+
 type Adjacency map[string]float64
 
-func (A Adjacency) Between(M ...VecMapping) {
+/// NewAdjacency computes the pairwise Adjacency between two or more
+/// corpora's VecMappings, over the vocabulary shared by all of them. Each
+/// VecMapping's vectors must share the same dimension (e.g. call WordVecs
+/// with the same maxDim for every corpus being compared); NewAdjacency
+/// returns an error rather than comparing mismatched dimensions.
+func NewAdjacency(M ...VecMapping) (A Adjacency, err error) {
 	sf := float64(len(M[0]))
 	for _, v := range M {
 		sf = math.Max(float64(len(v)), sf)
@@ -229,7 +433,7 @@ func (A Adjacency) Between(M ...VecMapping) {
 	for p, P := range M {
 		for k := range P {
 			ok := true
-			Qs := append(M[:p], M[p+1:]...)
+			Qs := without(M, p)
 			for q := 0; q < len(Qs) && ok; q++ {
 				_, ok = Qs[q][k]
 			}
@@ -238,24 +442,58 @@ func (A Adjacency) Between(M ...VecMapping) {
 			}
 		}
 	}
+	var dim int
+	for k := range V {
+		for _, P := range M {
+			if d := P[k].Len(); dim == 0 {
+				dim = d
+			} else if d != dim {
+				return nil, fmt.Errorf("litevec: NewAdjacency: mismatched vector dimensions (%d vs %d) for shared term %q; build every corpus's WordVecs with the same maxDim", dim, d, k)
+			}
+		}
+	}
 	for p, P := range M {
+		Qs := without(M, p)
 		for k := range V {
 			A[k] = 0
-			Qs := append(M[:p], M[p+1:]...)
 			for q, Q := range Qs {
 				var sigma float64
+				var counted float64
 				for t := range V {
-					// norm over the corporas' semantic similarity of k and t
+					// norm over the corporas' semantic similarity of k and t;
+					// skip terms with no cross-corpus similarity of their own
+					// (d == 0, e.g. a term zeroed out of both PPMI matrices),
+					// since they carry no comparable signal and would divide
+					// by zero.
 					c := mat.Dot(P[k], Q[k])
 					d := mat.Dot(P[t], Q[t])
+					if d == 0 {
+						continue
+					}
 					sigma += c / d
+					counted++
+				}
+				if counted > 0 {
+					sigma /= counted
 				}
-				n := float64(q)
-				sigma /= float64(len(V))
-				A[k] = (A[k]*n + sigma) / n
+				// running average over Qs; n is 1-indexed so the first
+				// corpus seeds A[k] instead of dividing by zero
+				n := float64(q + 1)
+				A[k] += (sigma - A[k]) / n
 			}
 		}
 	}
+	return
+}
+
+/// without returns a copy of M with the element at index p removed,
+/// leaving M itself untouched (unlike append(M[:p], M[p+1:]...), which
+/// would clobber M's backing array).
+func without(M []VecMapping, p int) []VecMapping {
+	rtn := make([]VecMapping, 0, len(M)-1)
+	rtn = append(rtn, M[:p]...)
+	rtn = append(rtn, M[p+1:]...)
+	return rtn
 }
 
 func (A Adjacency) DocSim() float64 {
@@ -0,0 +1,127 @@
+package litevec
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const sampleText = `the quick brown fox jumps over the lazy dog the dog barks at the fox the fox runs away from the dog`
+
+func TestDocPipelineConstellation(t *testing.T) {
+	D := MkDoc(MkText(sampleText))
+
+	N := D.PMIs(2)
+	N.DoNonZero(func(i, j int, v float64) {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			t.Fatalf("PMIs produced non-finite value at (%d,%d): %v", i, j, v)
+		}
+	})
+
+	M := D.WordVecs(2, nil)
+	if len(M.VecMapping) != D.VocabLength() {
+		t.Fatalf("WordVecs produced %d vectors, want %d", len(M.VecMapping), D.VocabLength())
+	}
+
+	n := 3
+	C := M.Constellation("fox", &n)
+	if len(C) != 3 {
+		t.Fatalf("Constellation returned %d terms, want 3", len(C))
+	}
+}
+
+// TestWordVecsRealisticTokenRatio guards against UnigramPs normalizing by
+// vocab size instead of token count: on a corpus where tokens outnumber
+// vocab by several times (ordinary for real text), that bug drove every
+// PMI value low enough that PPMIs' negative-sample shift zeroed the whole
+// matrix, collapsing WordVecs to an all-zero embedding.
+func TestWordVecsRealisticTokenRatio(t *testing.T) {
+	repeated := strings.Repeat(sampleText+" ", 5)
+	D := MkDoc(MkText(repeated))
+
+	M := D.WordVecs(2, nil)
+	if mat.Norm(M.Matrix, 2) == 0 {
+		t.Fatalf("WordVecs collapsed to an all-zero matrix on a realistic token/vocab ratio")
+	}
+}
+
+func TestAdjacencyDocSim(t *testing.T) {
+	A := MkDoc(MkText(`the quick brown fox jumps over the lazy dog the fox runs`))
+	B := MkDoc(MkText(`the quick brown fox sleeps under the lazy dog the fox hides under a tree near the old barn`))
+
+	// Corpora being compared must agree on vector dimension, so pin both
+	// to the same maxDim regardless of either one's own vocab size.
+	maxDim := 5
+	MA := A.WordVecs(2, &maxDim).VecMapping
+	MB := B.WordVecs(2, &maxDim).VecMapping
+
+	adj, err := NewAdjacency(MA, MB)
+	if err != nil {
+		t.Fatalf("NewAdjacency: %v", err)
+	}
+	sim := adj.DocSim()
+	if math.IsInf(sim, 0) || math.IsNaN(sim) {
+		t.Fatalf("DocSim is non-finite: %v", sim)
+	}
+}
+
+// TestAdjacencyMismatchedDims guards against NewAdjacency panicking (rather
+// than erroring) when compared corpora's WordVecs weren't built with a
+// shared maxDim, and so size their vectors to their own (different) vocab
+// lengths - the normal case for two ordinary documents of different sizes.
+func TestAdjacencyMismatchedDims(t *testing.T) {
+	A := MkDoc(MkText(`the quick brown fox jumps over the lazy dog the fox runs`))
+	B := MkDoc(MkText(`the quick brown fox sleeps under the lazy dog the fox hides under a tree near the old barn`))
+
+	MA := A.WordVecs(2, nil).VecMapping
+	MB := B.WordVecs(2, nil).VecMapping
+
+	if _, err := NewAdjacency(MA, MB); err == nil {
+		t.Fatalf("NewAdjacency did not error on mismatched vector dimensions")
+	}
+}
+
+// TestConstellationMMRDeterministic guards against ConstellationMMR's tie
+// resolution depending on Go's randomized map iteration order, which would
+// contradict its own doc comment ("lambda=1 reproduces Constellation's
+// ordering exactly").
+func TestConstellationMMRDeterministic(t *testing.T) {
+	D := MkDoc(MkText(sampleText))
+	m := D.WordVecs(2, nil).VecMapping
+
+	first := m.ConstellationMMR("fox", 5, 1.0)
+	for i := 0; i < 20; i++ {
+		if got := m.ConstellationMMR("fox", 5, 1.0); !textEqual(got, first) {
+			t.Fatalf("ConstellationMMR not deterministic: got %v, want %v", got, first)
+		}
+	}
+}
+
+func textEqual(a, b Text) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIncidencyKeywords(t *testing.T) {
+	D := MkDoc(MkText(sampleText))
+	I := NewIncidency(D, 2)
+
+	K := I.Keywords(nil)
+	if len(K) != D.VocabLength() {
+		t.Fatalf("Keywords returned %d terms, want %d", len(K), D.VocabLength())
+	}
+	for i := 1; i < len(K); i++ {
+		if I[K[i-1]] > I[K[i]] {
+			t.Fatalf("Keywords not sorted ascending by incidence: %v", K)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package litevec
+
+import (
+	"math/rand"
+
+	"github.com/james-bowman/sparse"
+	"gonum.org/v1/gonum/mat"
+)
+
+/// rsvdConfig holds the tunables for randomizedSVD: how many extra
+/// dimensions to sample beyond the target rank (oversample), how many
+/// power iterations to refine the sample with (nPowerIter), and the
+/// negative-sample shift used when weighting the PPMI matrix being
+/// factorized (negSamples).
+type rsvdConfig struct {
+	oversample int
+	nPowerIter int
+	negSamples int
+}
+
+func defaultRSVDConfig() rsvdConfig {
+	return rsvdConfig{oversample: 10, nPowerIter: 2, negSamples: 5}
+}
+
+/// RSVDOption tunes WordVecs' randomized SVD.
+type RSVDOption func(*rsvdConfig)
+
+/// WithOversample sets how many extra dimensions beyond the target rank
+/// are sampled before truncating (Halko/Martinsson's p); higher values
+/// improve accuracy at the cost of a larger intermediate basis.
+func WithOversample(p int) RSVDOption {
+	return func(c *rsvdConfig) { c.oversample = p }
+}
+
+/// WithPowerIterations sets how many power iterations refine the sampled
+/// basis (Halko/Martinsson's q); higher values improve accuracy on
+/// matrices with a slowly decaying singular spectrum at the cost of more
+/// sparse-dense multiplies.
+func WithPowerIterations(q int) RSVDOption {
+	return func(c *rsvdConfig) { c.nPowerIter = q }
+}
+
+/// WithNegativeSamples sets the SGNS-style negative sample count used to
+/// shift the PPMI matrix (see Doc.PPMIs).
+func WithNegativeSamples(k int) RSVDOption {
+	return func(c *rsvdConfig) { c.negSamples = k }
+}
+
+/// randomizedSVD computes an n x k orthonormal basis approximating the
+/// top-k left singular vectors of A (n x n), following Halko &
+/// Martinsson's randomized range finder: draw a Gaussian probe Omega (n x
+/// (k+p)), form Y = A*Omega, refine it with nPowerIter power iterations
+/// Y <- A(A^T Y) (re-orthonormalizing between steps for numerical
+/// stability), orthonormalize the result into Q, project A onto it
+/// (B = Q^T A), take a small dense SVD of B, and lift its left singular
+/// vectors back with Q.
+func randomizedSVD(A *sparse.CSR, k int, cfg rsvdConfig) *mat.Dense {
+	n, _ := A.Dims()
+	l := k + cfg.oversample
+	if l > n {
+		l = n
+	}
+
+	Omega := mat.NewDense(n, l, nil)
+	for i := 0; i < n; i++ {
+		for j := 0; j < l; j++ {
+			Omega.Set(i, j, rand.NormFloat64())
+		}
+	}
+
+	Y := mat.NewDense(n, l, nil)
+	Y.Mul(A, Omega)
+	Q := orthonormalizeColumns(Y)
+
+	for iter := 0; iter < cfg.nPowerIter; iter++ {
+		Z := new(mat.Dense)
+		Z.Mul(A.T(), Q)
+		Zq := orthonormalizeColumns(Z)
+		Y.Mul(A, Zq)
+		Q = orthonormalizeColumns(Y)
+	}
+
+	B := new(mat.Dense)
+	B.Mul(Q.T(), A)
+
+	svd := new(mat.SVD)
+	svd.Factorize(B, mat.SVDThin)
+	UB := new(mat.Dense)
+	svd.UTo(UB)
+
+	Uk := new(mat.Dense)
+	Uk.Mul(Q, UB)
+
+	rows, cols := Uk.Dims()
+	if k > cols {
+		k = cols
+	}
+	return mat.DenseCopyOf(Uk.Slice(0, rows, 0, k))
+}
+
+/// orthonormalizeColumns returns an orthonormal basis for the column
+/// space of Y via modified Gram-Schmidt, the re-orthonormalization step
+/// randomizedSVD runs between power iterations. A column whose residual
+/// norm is at most 1e-12 is left unnormalized (near zero) rather than
+/// dropped, so a rank-deficient Y yields some near-zero columns.
+func orthonormalizeColumns(Y *mat.Dense) *mat.Dense {
+	rows, cols := Y.Dims()
+	Q := mat.DenseCopyOf(Y)
+	for j := 0; j < cols; j++ {
+		col := make([]float64, rows)
+		for i := 0; i < rows; i++ {
+			col[i] = Q.At(i, j)
+		}
+		for p := 0; p < j; p++ {
+			var dot float64
+			for i := 0; i < rows; i++ {
+				dot += col[i] * Q.At(i, p)
+			}
+			for i := 0; i < rows; i++ {
+				col[i] -= dot * Q.At(i, p)
+			}
+		}
+		norm := mat.Norm(mat.NewVecDense(rows, col), 2)
+		if norm > 1e-12 {
+			for i := range col {
+				col[i] /= norm
+			}
+		}
+		Q.SetCol(j, col)
+	}
+	return Q
+}